@@ -1,15 +1,29 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -18,6 +32,12 @@ import (
 const (
 	bytesToFetch = 1024
 	timeout      = 30 * time.Second
+
+	// Retry/failover tuning for fetchRandomBytes.
+	maxFetchAttempts = 5
+	initialBackoff   = 500 * time.Millisecond
+	backoffFactor    = 2
+	maxBackoff       = 15 * time.Second
 )
 
 // --- Styles ---
@@ -63,6 +83,17 @@ var (
 			BorderForeground(current).
 			Foreground(subtle)
 
+	failColor = lipgloss.AdaptiveColor{Light: "#D70000", Dark: "#FF5F5F"}
+
+	failStyle = cardStyle.Copy().
+			BorderForeground(failColor).
+			Foreground(failColor)
+
+	currentFailStyle = cardStyle.Copy().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(current).
+				Foreground(failColor)
+
 	// Text Styles
 	titleStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#FFFDF5")).
@@ -84,6 +115,64 @@ type AnuQrngResponse struct {
 	Success bool    `json:"success"`
 }
 
+// --- Entropy Sources ---
+
+// Source is a registered provider of raw entropy bytes. New providers (a
+// local /dev/urandom seed, a LavaRand HTTP feed, a user-supplied HMAC key,
+// ...) are added by appending to allSources. /healthz reports a source as
+// unhealthy unless it also has an entry in sourceProbeURL, and the TUI's 'c'
+// key cycles only qr, anu and mix.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// allSources is the registry consulted by name-based lookups (fetchRandomBytes)
+// and by mix mode, which fans out to every entry here.
+var allSources = []Source{
+	qrandomSource{},
+	anuSource{},
+}
+
+func sourceByName(name string) (Source, bool) {
+	for _, s := range allSources {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// validateSource reports whether name is a source fetchRandomBytes knows how
+// to resolve ("mix" or a registered Source name). Callers should check this
+// before doing any retry/rate-limit accounting, since an unknown source is a
+// terminal error, not a transient one worth retrying.
+func validateSource(name string) error {
+	if name == "mix" {
+		return nil
+	}
+	if _, ok := sourceByName(name); ok {
+		return nil
+	}
+	return fmt.Errorf("unknown source: %s (use 'qr', 'anu', or 'mix')", name)
+}
+
+type qrandomSource struct{}
+
+func (qrandomSource) Name() string { return "qr" }
+
+func (qrandomSource) Fetch(ctx context.Context) ([]byte, error) {
+	return fetchQRandomBytes(ctx)
+}
+
+type anuSource struct{}
+
+func (anuSource) Name() string { return "anu" }
+
+func (anuSource) Fetch(ctx context.Context) ([]byte, error) {
+	return fetchAnuQrngBytes(ctx)
+}
+
 // --- Bubble Tea Model & Messages ---
 
 type resultType int
@@ -95,13 +184,19 @@ const (
 )
 
 type flipResult struct {
-	ones   int
-	zeros  int
-	winner resultType
+	ones      int
+	zeros     int
+	winner    resultType
+	sources   []string // sources that contributed to this flip (len > 1 for mix)
+	stats     statsReport
+	hasStats  bool // false for replayed and remote flips, whose raw bytes are not available
+	timestamp time.Time
+	rawHash   string
 }
 
 type model struct {
 	source       string
+	fallback     bool
 	results      []flipResult
 	loading      bool
 	err          error
@@ -113,6 +208,13 @@ type model struct {
 	onesInput    textinput.Model
 	zerosInput   textinput.Model
 	focusedInput int // 0 for ones, 1 for zeros
+	retryStatus  string
+	fetchEvents  chan tea.Msg
+	showStats    bool
+	replayMode   bool
+	historyMode  bool
+	historyList  list.Model
+	remoteAddr   string // when set, flips are fetched from a qcoin serve backend instead of directly
 }
 
 type flipMsg struct {
@@ -120,7 +222,15 @@ type flipMsg struct {
 	err    error
 }
 
-func initialModel(source string) model {
+// retryMsg reports an in-flight retry attempt so the TUI can show progress
+// instead of a frozen "Extracting entropy..." status.
+type retryMsg struct {
+	source      string
+	attempt     int
+	maxAttempts int
+}
+
+func initialModel(source string, fallback bool) model {
 	onesInput := textinput.New()
 	onesInput.Placeholder = "Message for ONES winner"
 	onesInput.Width = 30
@@ -133,6 +243,7 @@ func initialModel(source string) model {
 
 	return model{
 		source:       source,
+		fallback:     fallback,
 		results:      []flipResult{},
 		loading:      false,
 		onesMsg:      "ONES",
@@ -148,6 +259,25 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.historyMode {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.historyList.FilterState() != list.Filtering {
+			switch keyMsg.String() {
+			case "esc", "q", "h":
+				m.historyMode = false
+				return m, nil
+			}
+		}
+		if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.width = sizeMsg.Width
+			m.height = sizeMsg.Height
+			m.historyList.SetSize(m.width/2, m.height-4)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.historyList, cmd = m.historyList.Update(msg)
+		return m, cmd
+	}
+
 	if m.inputMode {
 		// Handle keys in input mode
 		switch msg := msg.(type) {
@@ -200,22 +330,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q":
 			return m, tea.Quit
 		case "enter":
+			if m.replayMode {
+				m.err = fmt.Errorf("replay mode: network fetch disabled")
+				return m, nil
+			}
 			if !m.loading {
 				m.loading = true
 				m.err = nil
-				return m, fetchAndFlipCmd(m.source)
+				m.retryStatus = ""
+				ch := make(chan tea.Msg)
+				m.fetchEvents = ch
+				if m.remoteAddr != "" {
+					go runFetchPipelineRemote(m.remoteAddr, m.source, m.fallback, ch)
+				} else {
+					go runFetchPipeline(m.source, m.fallback, ch)
+				}
+				return m, waitForFetchEvent(ch)
 			}
 		case "r":
 			m.results = []flipResult{}
 			return m, nil
 		case "c":
-			// Toggle between sources
-			if m.source == "qr" {
+			// Cycle through sources: qr -> anu -> mix -> qr
+			switch m.source {
+			case "qr":
 				m.source = "anu"
-			} else {
+			case "anu":
+				m.source = "mix"
+			default:
 				m.source = "qr"
 			}
 			return m, nil
+		case "f":
+			// Toggle cross-source failover
+			m.fallback = !m.fallback
+			return m, nil
+		case "s":
+			// Toggle the randomness-diagnostics detail pane
+			m.showStats = !m.showStats
+			return m, nil
+		case "h":
+			// Open the persisted flip history viewer
+			entries, err := loadHistoryForViewer()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			items := make([]list.Item, len(entries))
+			for i, e := range entries {
+				items[i] = historyItem{entry: e}
+			}
+			l := list.New(items, list.NewDefaultDelegate(), m.width/2, m.height-4)
+			l.Title = "Flip History"
+			m.historyList = l
+			m.historyMode = true
+			return m, nil
 		case "i":
 			// Enter input mode
 			m.inputMode = true
@@ -230,18 +399,57 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
+	case retryMsg:
+		m.retryStatus = fmt.Sprintf("Retrying %s (attempt %d/%d)…", msg.source, msg.attempt, msg.maxAttempts)
+		return m, waitForFetchEvent(m.fetchEvents)
+
 	case flipMsg:
 		m.loading = false
+		m.retryStatus = ""
+		m.fetchEvents = nil
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
 		m.results = append(m.results, msg.result)
+		if err := appendHistoryEntry(historyEntryFromFlip(msg.result, m.onesMsg, m.zerosMsg)); err != nil {
+			m.err = fmt.Errorf("flip recorded in-memory, but failed to persist to history: %w", err)
+		}
 	}
 
 	return m, nil
 }
 
+// cardStyleFor picks the flip card's style: a failing stats report always
+// wins (in red) over the normal winner coloring, since a fetch that failed
+// the randomness diagnostics shouldn't be celebrated.
+func cardStyleFor(winner resultType, statsFailed, isLatest bool) lipgloss.Style {
+	if statsFailed {
+		if isLatest {
+			return currentFailStyle
+		}
+		return failStyle
+	}
+
+	switch winner {
+	case resOnes:
+		if isLatest {
+			return currentOneStyle
+		}
+		return winOneStyle
+	case resZeros:
+		if isLatest {
+			return currentZeroStyle
+		}
+		return winZeroStyle
+	default:
+		if isLatest {
+			return currentTieStyle
+		}
+		return tieStyle
+	}
+}
+
 func (m model) View() string {
 	if m.width == 0 {
 		return "loading..."
@@ -256,6 +464,15 @@ func (m model) View() string {
 		return lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(b.String())
 	}
 
+	if m.historyMode {
+		left := m.historyList.View()
+		right := statusStyle.Render("No flips recorded yet.")
+		if item, ok := m.historyList.SelectedItem().(historyItem); ok {
+			right = historyDetailView(item)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	}
+
 	// 1. Header
 	header := titleStyle.Render("QCOIN - Quantum Flip")
 
@@ -277,35 +494,19 @@ func (m model) View() string {
 	var cards []string
 	for i, res := range visibleResults {
 		label := ""
-		style := tieStyle
-
-		// Check if this is the most recent result
-		isLatest := (i == len(visibleResults)-1) && len(m.results) > 0
-
 		switch res.winner {
 		case resOnes:
 			label = m.onesMsg
-			if isLatest {
-				style = currentOneStyle
-			} else {
-				style = winOneStyle
-			}
 		case resZeros:
 			label = m.zerosMsg
-			if isLatest {
-				style = currentZeroStyle
-			} else {
-				style = winZeroStyle
-			}
 		default:
 			label = "TIE"
-			if isLatest {
-				style = currentTieStyle
-			} else {
-				style = tieStyle
-			}
 		}
 
+		// Check if this is the most recent result
+		isLatest := (i == len(visibleResults)-1) && len(m.results) > 0
+		style := cardStyleFor(res.winner, res.hasStats && !res.stats.allPass(), isLatest)
+
 		content := fmt.Sprintf("%s\n\n1: %d\n0: %d", label, res.ones, res.zeros)
 		cards = append(cards, style.Render(content))
 	}
@@ -327,17 +528,44 @@ func (m model) View() string {
 			Render("No flips yet. Spin the quantum coin!")
 	}
 
-	// 3. Status Bar
+	// 3. Provenance panel for the most recent flip
+	var provenance string
+	if len(m.results) > 0 {
+		latest := m.results[len(m.results)-1]
+		provenance = statusStyle.Render(fmt.Sprintf("Provenance: %s", strings.Join(latest.sources, " + ")))
+	}
+
+	// 3b. Randomness diagnostics detail pane, toggled with 's'
+	var statsPanel string
+	if m.showStats && len(m.results) > 0 {
+		latest := m.results[len(m.results)-1]
+		if latest.hasStats {
+			statsPanel = renderStatsPanel(latest.stats)
+		} else {
+			statsPanel = statusStyle.Render("Stats unavailable for replayed and remote flips\n")
+		}
+	}
+
+	// 4. Status Bar
 	var status string
 	if m.err != nil {
 		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(fmt.Sprintf("Error: %v", m.err))
+	} else if m.loading && m.retryStatus != "" {
+		status = m.retryStatus
 	} else if m.loading {
 		status = "Extracting entropy..."
 	} else {
-		status = fmt.Sprintf("Source: %s | Total Flips: %d", strings.ToUpper(m.source), len(m.results))
+		fallbackState := "off"
+		if m.fallback {
+			fallbackState = "on"
+		}
+		status = fmt.Sprintf("Source: %s | Fallback: %s | Total Flips: %d", strings.ToUpper(m.source), fallbackState, len(m.results))
+		if m.remoteAddr != "" {
+			status += fmt.Sprintf(" | Remote: %s", m.remoteAddr)
+		}
 	}
 
-	help := statusStyle.Render("\nPress [Enter] to Flip • [r] to Reset • [c] to Change Source • [i] to Change Messages • [q] to Quit")
+	help := statusStyle.Render("\nPress [Enter] to Flip • [r] to Reset • [c] to Change Source • [f] to Toggle Fallback • [s] to Toggle Stats • [h] to View History • [i] to Change Messages • [q] to Quit")
 
 	// Layout Composition
 	return lipgloss.JoinVertical(
@@ -346,61 +574,125 @@ func (m model) View() string {
 		"\n",
 		carousel,
 		"\n",
+		provenance,
+		statsPanel,
 		status,
 		help,
 	)
 }
 
+// renderStatsPanel renders each test's p-value and pass/fail verdict for the
+// detail pane toggled with the 's' key.
+func renderStatsPanel(r statsReport) string {
+	lines := make([]string, 0, len(r.tests()))
+	for _, t := range r.tests() {
+		verdict := "PASS"
+		style := lipgloss.NewStyle().Foreground(special)
+		if !t.pass {
+			verdict = "FAIL"
+			style = lipgloss.NewStyle().Foreground(failColor)
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%-16s p=%.4f  %s", t.name, t.pValue, verdict)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
 // --- Commands ---
 
-func fetchAndFlipCmd(source string) tea.Cmd {
+// waitForFetchEvent listens for the next message emitted by runFetchPipeline.
+// Each retryMsg received re-arms the wait so the caller keeps draining the
+// same channel until a terminal flipMsg arrives.
+func waitForFetchEvent(ch chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
-		bytes, err := fetchRandomBytes(source)
-		if err != nil {
-			return flipMsg{err: err}
-		}
+		return <-ch
+	}
+}
 
-		ones, zeros := countBits(bytes)
+// runFetchPipeline drives fetchRandomBytes in the background and reports
+// progress/results on ch, mirroring the retry attempts as retryMsg values
+// before finally emitting a flipMsg.
+func runFetchPipeline(source string, fallback bool, ch chan tea.Msg) {
+	onRetry := func(attempt, max int, src string) {
+		ch <- retryMsg{source: src, attempt: attempt, maxAttempts: max}
+	}
 
-		res := flipResult{
-			ones:   ones,
-			zeros:  zeros,
-			winner: resTie,
-		}
+	bytes, sources, err := fetchRandomBytes(context.Background(), source, fallback, onRetry, nil)
+	if err != nil {
+		ch <- flipMsg{err: err}
+		return
+	}
 
-		if ones > zeros {
-			res.winner = resOnes
-		} else if zeros > ones {
-			res.winner = resZeros
-		}
+	ones, zeros := countBits(bytes)
 
-		return flipMsg{result: res}
+	res := flipResult{
+		ones:      ones,
+		zeros:     zeros,
+		winner:    resTie,
+		sources:   sources,
+		stats:     runStatsSuite(bytes, ones, zeros),
+		hasStats:  true,
+		timestamp: time.Now(),
+		rawHash:   rawHash(bytes),
 	}
+
+	if ones > zeros {
+		res.winner = resOnes
+	} else if zeros > ones {
+		res.winner = resZeros
+	}
+
+	ch <- flipMsg{result: res}
 }
 
 // --- Main ---
 
 func main() {
-	source := flag.String("s", "qr", "Source: qr (qrandom.io) or anu (ANU QRNG)")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		}
+	}
+
+	source := flag.String("s", "qr", "Source: qr (qrandom.io), anu (ANU QRNG), or mix (XOR of all registered sources)")
 	interactive := flag.Bool("i", false, "Start interactive TUI mode")
+	fallback := flag.Bool("fallback", false, "Try the other source if the preferred one fails after all retries")
+	stats := flag.Bool("stats", false, "Print NIST SP 800-22 style randomness diagnostics for the fetched buffer")
+	replay := flag.String("replay", "", "Replay a saved qcoin history.jsonl file instead of fetching over the network")
+	remote := flag.String("remote", "", "With -i, render flips served by a shared 'qcoin serve' backend instead of fetching directly, e.g. http://host:8080")
 	flag.Parse()
 
+	if *replay != "" {
+		runReplayMode(*replay, *source, *fallback, *interactive)
+		return
+	}
+
 	if *interactive {
-		p := tea.NewProgram(initialModel(*source), tea.WithAltScreen())
+		m := initialModel(*source, *fallback)
+		m.remoteAddr = *remote
+		p := tea.NewProgram(m, tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			fmt.Printf("Alas, there's been an error: %v", err)
 			os.Exit(1)
 		}
 	} else {
 		// Standard CLI Mode
-		runCLI(*source)
+		runCLI(*source, *fallback, *stats)
 	}
 }
 
 // --- Existing Logic (Refactored slightly for reuse) ---
 
-func runCLI(source string) {
-	bytes, err := fetchRandomBytes(source)
+func runCLI(source string, fallback, showStats bool) {
+	onRetry := func(attempt, max int, src string) {
+		fmt.Fprintf(os.Stderr, "Retrying %s (attempt %d/%d)…\n", src, attempt, max)
+	}
+
+	bytes, sources, err := fetchRandomBytes(context.Background(), source, fallback, onRetry, nil)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -410,14 +702,38 @@ func runCLI(source string) {
 
 	fmt.Printf("Ones: %d\n", ones)
 	fmt.Printf("Zeros: %d\n", zeros)
+	fmt.Printf("Sources: %s\n", strings.Join(sources, " + "))
 
+	var winner resultType
 	if ones > zeros {
+		winner = resOnes
 		fmt.Println("Result: ONES")
 	} else if zeros > ones {
+		winner = resZeros
 		fmt.Println("Result: ZEROS")
 	} else {
 		fmt.Println("Result: TIE")
 	}
+
+	res := flipResult{
+		ones: ones, zeros: zeros, winner: winner,
+		sources: sources, timestamp: time.Now(), rawHash: rawHash(bytes),
+	}
+	if err := appendHistoryEntry(historyEntryFromFlip(res, "ONES", "ZEROS")); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to persist flip to history: %v\n", err)
+	}
+
+	if showStats {
+		report := runStatsSuite(bytes, ones, zeros)
+		fmt.Println("\nRandomness diagnostics:")
+		for _, t := range report.tests() {
+			verdict := "PASS"
+			if !t.pass {
+				verdict = "FAIL"
+			}
+			fmt.Printf("  %-16s p=%.4f  %s\n", t.name, t.pValue, verdict)
+		}
+	}
 }
 
 func countBits(bytes []byte) (int, int) {
@@ -437,22 +753,354 @@ func countBits(bytes []byte) (int, int) {
 	return ones, zeros
 }
 
-func fetchRandomBytes(source string) ([]byte, error) {
-	switch source {
-	case "qr":
-		return fetchQRandomBytes()
-	case "anu":
-		return fetchAnuQrngBytes()
-	default:
-		return nil, fmt.Errorf("unknown source: %s (use 'qr' or 'anu')", source)
+// --- Randomness Diagnostics (NIST SP 800-22 subset) ---
+
+// blockFrequencyBlockSize is M in the NIST SP 800-22 block frequency test.
+const blockFrequencyBlockSize = 128
+
+// statPValueThreshold is the significance level below which a test is
+// considered a fail; 0.01 is the conventional NIST SP 800-22 default.
+const statPValueThreshold = 0.01
+
+// statResult is the outcome of a single randomness test.
+type statResult struct {
+	name   string
+	pValue float64
+	pass   bool
+}
+
+// statsReport bundles the subset of NIST SP 800-22 tests qcoin runs against
+// every fetched buffer: the whole point of qcoin is trusting quantum
+// entropy, so every flip gets a pass/fail summary alongside it.
+type statsReport struct {
+	monobit        statResult
+	blockFrequency statResult
+	runs           statResult
+}
+
+func (r statsReport) tests() []statResult {
+	return []statResult{r.monobit, r.blockFrequency, r.runs}
+}
+
+func (r statsReport) allPass() bool {
+	return r.monobit.pass && r.blockFrequency.pass && r.runs.pass
+}
+
+// runStatsSuite runs the monobit frequency, block frequency, and runs tests
+// against data. It fails open: a bad result never blocks the flip, it's
+// only ever reported alongside it.
+func runStatsSuite(data []byte, ones, zeros int) statsReport {
+	bits := bitsFromBytes(data)
+	return statsReport{
+		monobit:        monobitTest(ones, zeros),
+		blockFrequency: blockFrequencyTest(bits, blockFrequencyBlockSize),
+		runs:           runsTest(bits, ones, zeros),
+	}
+}
+
+// bitsFromBytes expands data into individual 0/1 bits, in the same
+// least-significant-bit-first order countBits already counts in.
+func bitsFromBytes(data []byte) []uint8 {
+	bits := make([]uint8, 0, len(data)*8)
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			if b&(1<<uint(i)) != 0 {
+				bits = append(bits, 1)
+			} else {
+				bits = append(bits, 0)
+			}
+		}
+	}
+	return bits
+}
+
+// monobitTest is the NIST SP 800-22 frequency (monobit) test: S = |ones -
+// zeros| / sqrt(n), p = erfc(S / sqrt(2)).
+func monobitTest(ones, zeros int) statResult {
+	n := float64(ones + zeros)
+	s := math.Abs(float64(ones-zeros)) / math.Sqrt(n)
+	p := math.Erfc(s / math.Sqrt2)
+	return statResult{name: "monobit", pValue: p, pass: p >= statPValueThreshold}
+}
+
+// blockFrequencyTest is the NIST SP 800-22 block frequency test: split bits
+// into blocks of size M, chi-square over each block's proportion of ones,
+// p from the upper incomplete gamma function.
+func blockFrequencyTest(bits []uint8, blockSize int) statResult {
+	numBlocks := len(bits) / blockSize
+	if numBlocks == 0 {
+		return statResult{name: "block frequency", pValue: 0, pass: false}
+	}
+
+	var chi2 float64
+	for i := 0; i < numBlocks; i++ {
+		block := bits[i*blockSize : (i+1)*blockSize]
+		var ones int
+		for _, b := range block {
+			ones += int(b)
+		}
+		pi := float64(ones) / float64(blockSize)
+		chi2 += (pi - 0.5) * (pi - 0.5)
+	}
+	chi2 *= 4 * float64(blockSize)
+
+	p := igamc(float64(numBlocks)/2, chi2/2)
+	return statResult{name: "block frequency", pValue: p, pass: p >= statPValueThreshold}
+}
+
+// runsTest is the NIST SP 800-22 runs test: first checks the monobit
+// prerequisite (proportion of ones close enough to 0.5), then counts runs
+// Vn and derives a p-value from it.
+func runsTest(bits []uint8, ones, _ int) statResult {
+	n := len(bits)
+	pi := float64(ones) / float64(n)
+
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(float64(n)) {
+		// Monobit prerequisite failed: the runs test isn't meaningful here.
+		return statResult{name: "runs", pValue: 0, pass: false}
+	}
+
+	vn := 1
+	for i := 0; i < n-1; i++ {
+		if bits[i] != bits[i+1] {
+			vn++
+		}
+	}
+
+	num := math.Abs(float64(vn) - 2*float64(n)*pi*(1-pi))
+	den := 2 * math.Sqrt(2*float64(n)) * pi * (1 - pi)
+	p := math.Erfc(num / den)
+	return statResult{name: "runs", pValue: p, pass: p >= statPValueThreshold}
+}
+
+// igamc is the regularized upper incomplete gamma function Q(a, x), computed
+// via a power series for x < a+1 and a continued fraction otherwise (the
+// same split used by the NIST STS reference implementation).
+func igamc(a, x float64) float64 {
+	if x <= 0 || a <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - igamSeries(a, x)
+	}
+	return igamContinuedFraction(a, x)
+}
+
+func igamSeries(a, x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+
+	ap := a
+	sum := 1 / a
+	del := sum
+	for n := 1; n < 1000; n++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*1e-17 {
+			break
+		}
+	}
+
+	lg, _ := math.Lgamma(a)
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+func igamContinuedFraction(a, x float64) float64 {
+	const tiny = 1e-300
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < 1e-17 {
+			break
+		}
+	}
+
+	lg, _ := math.Lgamma(a)
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+// onRetryFunc is called before each retry attempt beyond the first, so
+// callers (CLI output, the TUI status bar) can surface progress.
+type onRetryFunc func(attempt, maxAttempts int, source string)
+
+// sourceGate reports whether a fetch against the named source is currently
+// permitted. It is consulted before every real upstream attempt (including
+// retries, fallback, and each leg of mix), so it sees every concrete source
+// a request actually touches — not just the source name a caller asked for.
+// A nil gate permits everything; qcoin serve is the only caller that
+// supplies one.
+type sourceGate func(source string) bool
+
+// errRateLimited is wrapped into the error fetchWithRetry returns when a
+// sourceGate denies an attempt, so callers (qcoin serve's handlers) can
+// distinguish "rate limited" from a genuine upstream failure via errors.Is.
+var errRateLimited = errors.New("rate limit exceeded")
+
+// fetchRandomBytes resolves source ("qr", "anu", or "mix") to one or more
+// registered Sources, fetches bytesToFetch with retries, and returns the
+// resulting buffer along with the names of the sources that contributed to
+// it. For "qr"/"anu", fallback (if enabled) retries against the other
+// registered source once the requested one is exhausted.
+func fetchRandomBytes(ctx context.Context, source string, fallback bool, onRetry onRetryFunc, gate sourceGate) ([]byte, []string, error) {
+	if err := validateSource(source); err != nil {
+		return nil, nil, err
+	}
+
+	if source == "mix" {
+		return fetchMixBytes(ctx, onRetry, gate)
+	}
+
+	s, _ := sourceByName(source)
+
+	bytes, err := fetchWithRetry(ctx, s, onRetry, gate)
+	if err == nil {
+		return bytes, []string{s.Name()}, nil
+	}
+	if !fallback {
+		return nil, nil, err
+	}
+
+	other, ok := sourceByName(otherSource(source))
+	if !ok {
+		return nil, nil, err
+	}
+
+	bytes, otherErr := fetchWithRetry(ctx, other, onRetry, gate)
+	if otherErr != nil {
+		return nil, nil, fmt.Errorf("%s failed (%v), fallback %s also failed: %w", source, err, other.Name(), otherErr)
+	}
+
+	return bytes, []string{other.Name()}, nil
+}
+
+// otherSource returns the configured source to fall back to when source is
+// unavailable.
+func otherSource(source string) string {
+	if source == "qr" {
+		return "anu"
+	}
+	return "qr"
+}
+
+// fetchMixBytes fetches bytesToFetch from every registered source in
+// parallel and XORs the resulting streams together, so a single compromised
+// or biased provider cannot determine the outcome on its own.
+func fetchMixBytes(ctx context.Context, onRetry onRetryFunc, gate sourceGate) ([]byte, []string, error) {
+	type result struct {
+		name  string
+		bytes []byte
+		err   error
+	}
+
+	results := make(chan result, len(allSources))
+	for _, s := range allSources {
+		s := s
+		go func() {
+			bytes, err := fetchWithRetry(ctx, s, onRetry, gate)
+			results <- result{name: s.Name(), bytes: bytes, err: err}
+		}()
+	}
+
+	mixed := make([]byte, bytesToFetch)
+	var provenance []string
+	var errs []error
+
+	for range allSources {
+		r := <-results
+		switch {
+		case r.err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+		case len(r.bytes) != bytesToFetch:
+			errs = append(errs, fmt.Errorf("%s: expected %d bytes, got %d", r.name, bytesToFetch, len(r.bytes)))
+		default:
+			for i, b := range r.bytes {
+				mixed[i] ^= b
+			}
+			provenance = append(provenance, r.name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("mix: %d of %d sources failed: %w", len(errs), len(allSources), errors.Join(errs...))
+	}
+
+	sort.Strings(provenance)
+	return mixed, provenance, nil
+}
+
+// fetchWithRetry wraps s.Fetch with jittered exponential backoff:
+// initialBackoff, doubling each attempt up to maxBackoff, capped at
+// maxFetchAttempts tries. gate, if non-nil, is consulted before every real
+// attempt (including retries) so a rate limit applies to every upstream
+// call this source actually makes, not just the first.
+func fetchWithRetry(ctx context.Context, s Source, onRetry onRetryFunc, gate sourceGate) ([]byte, error) {
+	var lastErr error
+	backoff := initialBackoff
+
+	for attempt := 1; attempt <= maxFetchAttempts; attempt++ {
+		if gate != nil && !gate(s.Name()) {
+			return nil, fmt.Errorf("%s: %w", s.Name(), errRateLimited)
+		}
+
+		bytes, err := s.Fetch(ctx)
+		if err == nil {
+			return bytes, nil
+		}
+		lastErr = err
+
+		if attempt == maxFetchAttempts {
+			break
+		}
+
+		if onRetry != nil {
+			onRetry(attempt+1, maxFetchAttempts, s.Name())
+		}
+
+		time.Sleep(jitter(backoff))
+
+		backoff *= backoffFactor
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
 	}
+
+	return nil, fmt.Errorf("%s: giving up after %d attempts: %w", s.Name(), maxFetchAttempts, lastErr)
+}
+
+// jitter returns a random duration in [d/2, d] to avoid synchronized
+// retries (a.k.a. thundering herd) against the upstream QRNG services.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
 }
 
-func fetchQRandomBytes() ([]byte, error) {
+func fetchQRandomBytes(ctx context.Context) ([]byte, error) {
 	client := &http.Client{Timeout: timeout}
 
 	url := fmt.Sprintf("https://qrandom.io/api/random/binary?bytes=%d", bytesToFetch)
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build qrandom.io request: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("qrandom.io request failed: %w", err)
 	}
@@ -467,7 +1115,11 @@ func fetchQRandomBytes() ([]byte, error) {
 		return nil, fmt.Errorf("failed to parse qrandom.io response: %w", err)
 	}
 
-	binaryResp, err := client.Get(qrResp.BinaryURL)
+	binaryReq, err := http.NewRequestWithContext(ctx, http.MethodGet, qrResp.BinaryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build binary data request: %w", err)
+	}
+	binaryResp, err := client.Do(binaryReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch binary data: %w", err)
 	}
@@ -485,11 +1137,15 @@ func fetchQRandomBytes() ([]byte, error) {
 	return bytes, nil
 }
 
-func fetchAnuQrngBytes() ([]byte, error) {
+func fetchAnuQrngBytes(ctx context.Context) ([]byte, error) {
 	client := &http.Client{Timeout: timeout}
 
 	url := fmt.Sprintf("https://qrng.anu.edu.au/API/jsonI.php?length=%d&type=uint8", bytesToFetch)
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ANU QRNG request: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("ANU QRNG request failed: %w", err)
 	}
@@ -514,3 +1170,655 @@ func fetchAnuQrngBytes() ([]byte, error) {
 
 	return anuResp.Data, nil
 }
+
+// --- Flip History Persistence ---
+
+// historyEntry is the durable, rolling-log form of a flip: enough to
+// reproduce and audit an experiment after the process exits, without
+// keeping the raw entropy bytes around.
+type historyEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Sources   []string  `json:"sources"`
+	RawHash   string    `json:"raw_hash"`
+	Ones      int       `json:"ones"`
+	Zeros     int       `json:"zeros"`
+	Winner    string    `json:"winner"`
+	OnesMsg   string    `json:"ones_msg"`
+	ZerosMsg  string    `json:"zeros_msg"`
+}
+
+func historyEntryFromFlip(res flipResult, onesMsg, zerosMsg string) historyEntry {
+	return historyEntry{
+		Timestamp: res.timestamp,
+		Sources:   res.sources,
+		RawHash:   res.rawHash,
+		Ones:      res.ones,
+		Zeros:     res.zeros,
+		Winner:    winnerLabel(res.winner),
+		OnesMsg:   onesMsg,
+		ZerosMsg:  zerosMsg,
+	}
+}
+
+func winnerLabel(w resultType) string {
+	switch w {
+	case resOnes:
+		return "ones"
+	case resZeros:
+		return "zeros"
+	default:
+		return "tie"
+	}
+}
+
+func winnerFromLabel(label string) resultType {
+	switch label {
+	case "ones":
+		return resOnes
+	case "zeros":
+		return resZeros
+	default:
+		return resTie
+	}
+}
+
+// rawHash returns a hex-encoded SHA-256 digest of a fetched buffer, so the
+// history log can record provenance without storing the raw entropy itself.
+func rawHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// qcoinDataDir resolves the directory flip history is stored under,
+// following the XDG base directory spec with a $HOME/.local/share fallback.
+func qcoinDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "qcoin"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "qcoin"), nil
+}
+
+func historyFilePath() (string, error) {
+	dir, err := qcoinDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// appendHistoryEntry appends entry to the rolling append-only history log,
+// creating the data directory and file on first use.
+func appendHistoryEntry(entry historyEntry) error {
+	path, err := historyFilePath()
+	if err != nil {
+		return fmt.Errorf("resolve history file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode history entry: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write history entry: %w", err)
+	}
+	return nil
+}
+
+// loadHistory reads every entry from a qcoin history.jsonl file, in the
+// order they were recorded.
+func loadHistory(path string) ([]historyEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("parse history entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// loadHistoryForViewer loads the default history log for the TUI history
+// viewer, treating "no history yet" as an empty list rather than an error.
+func loadHistoryForViewer() ([]historyEntry, error) {
+	path, err := historyFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("resolve history file: %w", err)
+	}
+	entries, err := loadHistory(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load history: %w", err)
+	}
+	return entries, nil
+}
+
+// historyItem adapts a historyEntry to the bubbles/list item interface for
+// the TUI's history viewer.
+type historyItem struct {
+	entry historyEntry
+}
+
+func (i historyItem) Title() string {
+	return fmt.Sprintf("%s  %s", i.entry.Timestamp.Format(time.RFC3339), strings.Join(i.entry.Sources, "+"))
+}
+
+func (i historyItem) Description() string {
+	return fmt.Sprintf("ones=%d zeros=%d winner=%s", i.entry.Ones, i.entry.Zeros, i.entry.Winner)
+}
+
+func (i historyItem) FilterValue() string { return i.Title() }
+
+// historyDetailView renders the right-hand detail pane for the selected
+// entry in the history viewer.
+func historyDetailView(item historyItem) string {
+	e := item.entry
+	lines := []string{
+		titleStyle.Render("Flip Detail"),
+		"",
+		fmt.Sprintf("Timestamp: %s", e.Timestamp.Format(time.RFC3339)),
+		fmt.Sprintf("Sources:   %s", strings.Join(e.Sources, " + ")),
+		fmt.Sprintf("Raw hash:  %s", e.RawHash),
+		fmt.Sprintf("Ones:      %d", e.Ones),
+		fmt.Sprintf("Zeros:     %d", e.Zeros),
+		fmt.Sprintf("Winner:    %s", e.Winner),
+		fmt.Sprintf("Ones msg:  %s", e.OnesMsg),
+		fmt.Sprintf("Zeros msg: %s", e.ZerosMsg),
+	}
+	return lipgloss.NewStyle().Padding(0, 2).Render(strings.Join(lines, "\n"))
+}
+
+// runExport implements `qcoin export --format=csv|json`, dumping the
+// persisted history log to stdout.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "Export format: json or csv")
+	fs.Parse(args)
+
+	entries, err := loadHistoryForViewer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read history: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"timestamp", "sources", "raw_hash", "ones", "zeros", "winner", "ones_msg", "zeros_msg"})
+		for _, e := range entries {
+			w.Write([]string{
+				e.Timestamp.Format(time.RFC3339),
+				strings.Join(e.Sources, "+"),
+				e.RawHash,
+				strconv.Itoa(e.Ones),
+				strconv.Itoa(e.Zeros),
+				e.Winner,
+				e.OnesMsg,
+				e.ZerosMsg,
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use 'json' or 'csv')\n", *format)
+		os.Exit(1)
+	}
+}
+
+// runReplayMode loads a saved history log and renders it without touching
+// the network: in TUI mode it repopulates the carousel, in CLI mode it
+// prints a summary of each recorded flip.
+func runReplayMode(path, source string, fallback, interactive bool) {
+	entries, err := loadHistory(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load replay file: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !interactive {
+		for _, e := range entries {
+			fmt.Printf("%s | %s | ones=%d zeros=%d winner=%s\n",
+				e.Timestamp.Format(time.RFC3339), strings.Join(e.Sources, "+"), e.Ones, e.Zeros, e.Winner)
+		}
+		return
+	}
+
+	results := make([]flipResult, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, flipResult{
+			ones:      e.Ones,
+			zeros:     e.Zeros,
+			winner:    winnerFromLabel(e.Winner),
+			sources:   e.Sources,
+			rawHash:   e.RawHash,
+			timestamp: e.Timestamp,
+		})
+	}
+
+	m := initialModel(source, fallback)
+	m.results = results
+	m.replayMode = true
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		m.onesMsg = last.OnesMsg
+		m.zerosMsg = last.ZerosMsg
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Alas, there's been an error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// --- Serve Mode ---
+
+// flipAPIResponse is the JSON body returned by GET /flip, and the shape
+// runFetchPipelineRemote parses when the TUI renders flips from a shared
+// qcoin serve backend instead of fetching directly.
+type flipAPIResponse struct {
+	Ones   int       `json:"ones"`
+	Zeros  int       `json:"zeros"`
+	Winner string    `json:"winner"`
+	Source string    `json:"source"`
+	Ts     time.Time `json:"ts"`
+}
+
+const (
+	serveMaxBytesPerRequest = 8192
+	serveRateBurst          = 5
+	serveRateRefillPerSec   = 1
+	serveCacheCapacity      = 64
+)
+
+// tokenBucket is a simple per-source rate limiter so a busy client can't
+// exhaust a provider's (e.g. ANU's) quota on qcoin's behalf.
+type tokenBucket struct {
+	mu           sync.Mutex
+	tokens       float64
+	max          float64
+	refillPerSec float64
+	last         time.Time
+}
+
+func newTokenBucket(max, refillPerSec float64) *tokenBucket {
+	return &tokenBucket{tokens: max, max: max, refillPerSec: refillPerSec, last: time.Now()}
+}
+
+func (b *tokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.max, b.tokens+now.Sub(b.last).Seconds()*b.refillPerSec)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// flipCacheEntry holds the (possibly still in-flight) result for one
+// request-time bucket.
+type flipCacheEntry struct {
+	ready   chan struct{}
+	result  flipResult
+	sources []string
+	err     error
+}
+
+// flipCache is an LRU keyed by (request-time bucket, source, fallback): a
+// burst of /flip calls for the same source and fallback setting within the
+// same second share a single upstream fetch instead of hammering the
+// source. Keying in source and fallback keeps concurrent requests for
+// different sources from colliding on the same cache entry.
+type flipCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    []string
+	entries  map[string]*flipCacheEntry
+}
+
+func newFlipCache(capacity int) *flipCache {
+	return &flipCache{capacity: capacity, entries: make(map[string]*flipCacheEntry)}
+}
+
+// flipCacheKey builds the composite key for a given request-time bucket,
+// source, and fallback setting.
+func flipCacheKey(bucket int64, source string, fallback bool) string {
+	return fmt.Sprintf("%d|%s|%t", bucket, source, fallback)
+}
+
+func (c *flipCache) fetch(key string, fn func() (flipResult, []string, error)) (flipResult, []string, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		// Move the key to the most-recently-used end of the eviction order.
+		for i, k := range c.order {
+			if k == key {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+		c.order = append(c.order, key)
+		c.mu.Unlock()
+		<-e.ready
+		return e.result, e.sources, e.err
+	}
+
+	e := &flipCacheEntry{ready: make(chan struct{})}
+	c.entries[key] = e
+	c.order = append(c.order, key)
+	for len(c.order) > c.capacity {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.mu.Unlock()
+
+	e.result, e.sources, e.err = fn()
+	close(e.ready)
+	return e.result, e.sources, e.err
+}
+
+// flipServer holds the mutable state backing `qcoin serve`: per-source rate
+// limiters, the request-bucket cache, and last-known-healthy timestamps.
+type flipServer struct {
+	cache *flipCache
+
+	limitersMu sync.Mutex
+	limiters   map[string]*tokenBucket
+
+	healthMu sync.Mutex
+	health   map[string]time.Time
+}
+
+// registeredSourceNames returns the fixed set of concrete source names
+// qcoin serve rate-limits. "mix" isn't included here: it isn't a source a
+// gate is ever consulted with directly — a mix request is rate-limited via
+// the concrete qr/anu buckets each of its legs actually fetches from.
+func registeredSourceNames() []string {
+	names := make([]string, 0, len(allSources))
+	for _, s := range allSources {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func newFlipServer() *flipServer {
+	s := &flipServer{
+		cache:    newFlipCache(serveCacheCapacity),
+		limiters: make(map[string]*tokenBucket),
+		health:   make(map[string]time.Time),
+	}
+	// Pre-populate limiters for the fixed set of registered sources, rather
+	// than lazily keying off client-supplied strings: callers always
+	// validate the source before calling allow, but allocating up front
+	// means allow never has to trust untrusted input as a map key.
+	for _, name := range registeredSourceNames() {
+		s.limiters[name] = newTokenBucket(serveRateBurst, serveRateRefillPerSec)
+	}
+	return s
+}
+
+func (s *flipServer) allow(source string) bool {
+	s.limitersMu.Lock()
+	b, ok := s.limiters[source]
+	s.limitersMu.Unlock()
+	if !ok {
+		return false
+	}
+	return b.Allow()
+}
+
+func (s *flipServer) handleFlip(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "qr"
+	}
+	if err := validateSource(source); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fallback := r.URL.Query().Get("fallback") == "true"
+
+	// s.allow is threaded in as the gate, not checked once up front: a
+	// fallback or mix request can touch a source other than the one named
+	// in the query string, and every concrete source it actually fetches
+	// from must be rate-limited, not just the requested name.
+	bucket := time.Now().Unix()
+	res, sources, err := s.cache.fetch(flipCacheKey(bucket, source, fallback), func() (flipResult, []string, error) {
+		data, srcs, ferr := fetchRandomBytes(r.Context(), source, fallback, nil, s.allow)
+		if ferr != nil {
+			return flipResult{}, nil, ferr
+		}
+		ones, zeros := countBits(data)
+		fr := flipResult{ones: ones, zeros: zeros, winner: resTie}
+		if ones > zeros {
+			fr.winner = resOnes
+		} else if zeros > ones {
+			fr.winner = resZeros
+		}
+		return fr, srcs, nil
+	})
+	if err != nil {
+		if errors.Is(err, errRateLimited) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+
+	resp := flipAPIResponse{
+		Ones:   res.ones,
+		Zeros:  res.zeros,
+		Winner: winnerLabel(res.winner),
+		Source: strings.Join(sources, "+"),
+		Ts:     time.Now(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *flipServer) handleBytes(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "qr"
+	}
+
+	n := bytesToFetch
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid n", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+	if n > serveMaxBytesPerRequest {
+		n = serveMaxBytesPerRequest
+	}
+
+	src, ok := sourceByName(source)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown source: %s (use 'qr' or 'anu')", source), http.StatusBadRequest)
+		return
+	}
+
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		// s.allow is threaded in as the gate so it's charged per upstream
+		// fetch, not once per incoming request: satisfying n bytes can take
+		// several chunks, and each one is a real call against the quota.
+		chunk, err := fetchWithRetry(r.Context(), src, nil, s.allow)
+		if err != nil {
+			if errors.Is(err, errRateLimited) {
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+			} else {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			}
+			return
+		}
+		out = append(out, chunk...)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(out[:n])
+}
+
+// sourceProbeURL is the lightweight endpoint each source is HEAD-probed
+// against for /healthz, cheaper than pulling a full entropy buffer.
+var sourceProbeURL = map[string]string{
+	"qr":  "https://qrandom.io/",
+	"anu": "https://qrng.anu.edu.au/",
+}
+
+func probeSource(ctx context.Context, name string) error {
+	url, ok := sourceProbeURL[name]
+	if !ok {
+		return fmt.Errorf("no probe URL registered for source %s", name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *flipServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	type sourceStatus struct {
+		Healthy     bool      `json:"healthy"`
+		LastSuccess time.Time `json:"last_success,omitempty"`
+		Error       string    `json:"error,omitempty"`
+	}
+
+	statuses := make(map[string]sourceStatus, len(allSources))
+	for _, src := range allSources {
+		err := probeSource(r.Context(), src.Name())
+
+		s.healthMu.Lock()
+		if err == nil {
+			s.health[src.Name()] = time.Now()
+		}
+		last := s.health[src.Name()]
+		s.healthMu.Unlock()
+
+		st := sourceStatus{Healthy: err == nil, LastSuccess: last}
+		if err != nil {
+			st.Error = err.Error()
+		}
+		statuses[src.Name()] = st
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// runServe implements `qcoin serve --addr :8080`, exposing flips, raw
+// entropy bytes, and source health over HTTP so other tools can consume
+// qcoin without embedding it.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	fs.Parse(args)
+
+	srv := newFlipServer()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flip", srv.handleFlip)
+	mux.HandleFunc("/bytes", srv.handleBytes)
+	mux.HandleFunc("/healthz", srv.handleHealthz)
+
+	fmt.Printf("qcoin serve listening on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFetchPipelineRemote is the --remote counterpart to runFetchPipeline: it
+// renders flips served by a `qcoin serve` backend instead of fetching
+// directly, so multiple TUIs can share one upstream connection.
+func runFetchPipelineRemote(remoteAddr, source string, fallback bool, ch chan tea.Msg) {
+	url := fmt.Sprintf("%s/flip?source=%s&fallback=%t", strings.TrimRight(remoteAddr, "/"), source, fallback)
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		ch <- flipMsg{err: fmt.Errorf("remote fetch failed: %w", err)}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		ch <- flipMsg{err: fmt.Errorf("remote returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))}
+		return
+	}
+
+	var apiResp flipAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		ch <- flipMsg{err: fmt.Errorf("failed to parse remote response: %w", err)}
+		return
+	}
+
+	ch <- flipMsg{result: flipResult{
+		ones:      apiResp.Ones,
+		zeros:     apiResp.Zeros,
+		winner:    winnerFromLabel(apiResp.Winner),
+		sources:   strings.Split(apiResp.Source, "+"),
+		timestamp: apiResp.Ts,
+	}}
+}